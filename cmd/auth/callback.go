@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// callbackServer is an ephemeral loopback HTTP server that the browser hits
+// once the user has authorized a login session, sparing runLogin the 2s
+// poll loop.
+type callbackServer struct {
+	listener net.Listener
+	state    string
+	done     chan callbackResult
+}
+
+type callbackResult struct {
+	session *authorizedSession
+	err     error
+}
+
+// newCallbackServer binds an ephemeral port on the loopback interface. The
+// caller is expected to fall back to polling if this returns an error, e.g.
+// because loopback binding isn't permitted in the current environment.
+func newCallbackServer() (*callbackServer, error) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("couldn't bind a loopback callback port: %w", err)
+	}
+
+	state, err := randomState()
+	if err != nil {
+		lis.Close()
+		return nil, fmt.Errorf("couldn't generate a CSRF state value: %w", err)
+	}
+
+	return &callbackServer{
+		listener: lis,
+		state:    state,
+		done:     make(chan callbackResult, 1),
+	}, nil
+}
+
+func randomState() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// augmentAuthURL appends the loopback redirect_uri and CSRF state to the
+// auth URL returned by the Labs API, then starts serving /callback in the
+// background.
+func (cb *callbackServer) augmentAuthURL(authURL string) string {
+	u, err := url.Parse(authURL)
+	if err != nil {
+		return authURL
+	}
+
+	q := u.Query()
+	q.Set("redirect_uri", fmt.Sprintf("http://%s/callback", cb.listener.Addr()))
+	q.Set("state", cb.state)
+	u.RawQuery = q.Encode()
+
+	go cb.serve()
+
+	return u.String()
+}
+
+func (cb *callbackServer) serve() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", cb.handleCallback)
+
+	srv := &http.Server{Handler: mux}
+	srv.Serve(cb.listener)
+}
+
+func (cb *callbackServer) handleCallback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	if q.Get("state") != cb.state {
+		http.Error(w, "invalid state", http.StatusBadRequest)
+		cb.done <- callbackResult{err: fmt.Errorf("state mismatch in callback request")}
+		return
+	}
+
+	sessionID := q.Get("session")
+	accessToken := q.Get("token")
+	if sessionID == "" || accessToken == "" {
+		http.Error(w, "missing session or token", http.StatusBadRequest)
+		cb.done <- callbackResult{err: fmt.Errorf("callback request missing session or token")}
+		return
+	}
+
+	var expiresAt time.Time
+	if exp := q.Get("expires_at"); exp != "" {
+		expiresAt, _ = time.Parse(time.RFC3339, exp)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, callbackSuccessHTML)
+
+	cb.done <- callbackResult{session: &authorizedSession{
+		sessionID:    sessionID,
+		accessToken:  accessToken,
+		refreshToken: q.Get("refresh_token"),
+		expiresAt:    expiresAt,
+	}}
+}
+
+// wait blocks until the browser hits /callback or ctx is done, then closes
+// the listener, which stops the server. A failure of the server itself is
+// not reported here; wait keeps waiting on ctx.
+func (cb *callbackServer) wait(ctx context.Context) (*authorizedSession, error) {
+	defer cb.listener.Close()
+
+	select {
+	case res := <-cb.done:
+		return res.session, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+const callbackSuccessHTML = `<!DOCTYPE html>
+<html>
+<head><title>labctl login</title></head>
+<body style="font-family: sans-serif; text-align: center; margin-top: 10%;">
+<h1>You're all set!</h1>
+<p>You can close this tab and return to the terminal.</p>
+</body>
+</html>
+`
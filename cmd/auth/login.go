@@ -9,6 +9,7 @@ import (
 	"github.com/skratchdot/open-golang/open"
 	"github.com/spf13/cobra"
 
+	"github.com/iximiuz/labctl/internal/credstore"
 	"github.com/iximiuz/labctl/internal/labcli"
 	"github.com/iximiuz/labctl/internal/ssh"
 )
@@ -20,6 +21,12 @@ const (
 type loginOptions struct {
 	sessionID   string
 	accessToken string
+	noCallback  bool
+	fromEnv     bool
+	fromStdin   bool
+	skipSSH     bool
+	noUploadKey bool
+	profile     string
 }
 
 func newLoginCommand(cli labcli.CLI) *cobra.Command {
@@ -40,6 +47,11 @@ func newLoginCommand(cli labcli.CLI) *cobra.Command {
 					"Session ID must be provided if access token is specified.",
 				)
 			}
+			if opts.fromEnv && opts.fromStdin {
+				return labcli.NewStatusError(1,
+					"Only one of --from-env or --from-stdin can be used at a time.",
+				)
+			}
 
 			return labcli.WrapStatusError(runLogin(cmd.Context(), cli, opts))
 		},
@@ -61,20 +73,69 @@ func newLoginCommand(cli labcli.CLI) *cobra.Command {
 		"",
 		`Access token`,
 	)
+	flags.BoolVar(
+		&opts.noCallback,
+		"no-callback",
+		false,
+		`Always poll for session authorization instead of starting a local loopback callback server`,
+	)
+	flags.BoolVar(
+		&opts.fromEnv,
+		"from-env",
+		false,
+		`Read credentials from LABCTL_SESSION_ID and LABCTL_ACCESS_TOKEN instead of starting a browser flow (for CI)`,
+	)
+	flags.BoolVar(
+		&opts.fromStdin,
+		"from-stdin",
+		false,
+		`Read credentials as a {"session_id":"...","access_token":"..."} JSON blob from stdin (for CI)`,
+	)
+	flags.BoolVar(
+		&opts.skipSSH,
+		"skip-ssh",
+		false,
+		`Skip generating an SSH identity (useful for ephemeral CI runners)`,
+	)
+	flags.BoolVar(
+		&opts.noUploadKey,
+		"no-upload-key",
+		false,
+		`Don't upload the generated SSH public key to your Labs account`,
+	)
+	flags.StringVar(
+		&opts.profile,
+		"profile",
+		"",
+		`Named profile to log in as (default: $LABCTL_PROFILE or "default")`,
+	)
 
 	return cmd
 }
 
 func runLogin(ctx context.Context, cli labcli.CLI, opts loginOptions) error {
-	if cli.Config().SessionID != "" && cli.Config().AccessToken != "" {
+	profile := resolveProfile(opts.profile)
+	if err := cli.SelectProfile(profile); err != nil {
+		return fmt.Errorf("couldn't select profile %q: %w", profile, err)
+	}
+
+	cfg := cli.Config()
+	if token, err := credstore.LoadAccessToken(cfg); cfg.SessionID != "" && err == nil && token != "" {
 		return labcli.NewStatusError(1,
-			"Already logged in. Use 'labctl auth logout' first if you want to log in as a different user.",
+			fmt.Sprintf(
+				"Already logged in to profile %q. Use 'labctl auth logout --profile %s' first if you want to log in as a different user.",
+				profile, profile,
+			),
 		)
 	}
 
+	if opts.fromEnv || opts.fromStdin {
+		return runNonInteractiveLogin(ctx, cli, opts)
+	}
+
 	if opts.sessionID != "" && opts.accessToken != "" {
 		cli.Client().SetCredentials(opts.sessionID, opts.accessToken)
-		if err := saveSessionAndGenerateSSHIdentity(cli, opts.sessionID, opts.accessToken); err != nil {
+		if err := saveSessionAndGenerateSSHIdentity(ctx, cli, opts.sessionID, opts.accessToken, "", time.Time{}, opts); err != nil {
 			return err
 		}
 		cli.PrintAux("Authenticated.\n")
@@ -89,9 +150,18 @@ func runLogin(ctx context.Context, cli labcli.CLI, opts loginOptions) error {
 	accessToken := ses.AccessToken
 	cli.Client().SetCredentials(ses.ID, accessToken)
 
-	cli.PrintAux("Opening %s in your browser...\n", ses.AuthURL)
+	authURL := ses.AuthURL
+
+	var cb *callbackServer
+	if !opts.noCallback {
+		if cb, err = newCallbackServer(); err == nil {
+			authURL = cb.augmentAuthURL(authURL)
+		}
+	}
+
+	cli.PrintAux("Opening %s in your browser...\n", authURL)
 
-	if err := open.Run(ses.AuthURL); err != nil {
+	if err := open.Run(authURL); err != nil {
 		cli.PrintAux("Couldn't open the browser. Copy the above URL into a browser manually and follow the instructions on the page.\n")
 	}
 
@@ -105,34 +175,91 @@ func runLogin(ctx context.Context, cli labcli.CLI, opts loginOptions) error {
 	ctx, cancel := context.WithTimeout(ctx, loginSessionTimeout)
 	defer cancel()
 
-	for ctx.Err() == nil {
-		if ses, err := cli.Client().GetSession(ctx, ses.ID); err == nil && ses.Authenticated {
-			s.FinalMSG = "Waiting for the session to be authorized... Done.\n"
+	var authorized *authorizedSession
+	if cb != nil {
+		authorized, err = cb.wait(ctx)
+		if err != nil {
+			cli.PrintAux("Callback login didn't complete (%s), falling back to polling...\n", err)
+			authorized = nil
+		}
+	}
+	if authorized == nil {
+		authorized, err = pollForSession(ctx, cli, ses.ID)
+		if err != nil {
 			s.Stop()
+			return err
+		}
+	}
 
-			if err := saveSessionAndGenerateSSHIdentity(cli, ses.ID, accessToken); err != nil {
-				return err
-			}
+	s.FinalMSG = "Waiting for the session to be authorized... Done.\n"
+	s.Stop()
+
+	if err := saveSessionAndGenerateSSHIdentity(
+		ctx, cli, authorized.sessionID, authorized.accessToken, authorized.refreshToken, authorized.expiresAt, opts,
+	); err != nil {
+		return err
+	}
+
+	cli.PrintAux("\nSession authorized. You can now use labctl commands.\n")
+	return nil
+}
 
-			cli.PrintAux("\nSession authorized. You can now use labctl commands.\n")
-			return nil
+// authorizedSession carries the credentials collected once a login session
+// has been authorized, regardless of whether that happened via the loopback
+// callback or the polling fallback.
+type authorizedSession struct {
+	sessionID    string
+	accessToken  string
+	refreshToken string
+	expiresAt    time.Time
+}
+
+// pollForSession polls GetSession every 2 seconds until the session is
+// authorized or ctx is done.
+func pollForSession(ctx context.Context, cli labcli.CLI, sessionID string) (*authorizedSession, error) {
+	for ctx.Err() == nil {
+		if ses, err := cli.Client().GetSession(ctx, sessionID); err == nil && ses.Authenticated {
+			return &authorizedSession{
+				sessionID:    ses.ID,
+				accessToken:  ses.AccessToken,
+				refreshToken: ses.RefreshToken,
+				expiresAt:    ses.ExpiresAt,
+			}, nil
 		}
 
 		time.Sleep(2 * time.Second)
 	}
 
-	return nil
+	return nil, ctx.Err()
 }
 
-func saveSessionAndGenerateSSHIdentity(cli labcli.CLI, sessionID, accessToken string) error {
-	cli.Config().SessionID = sessionID
-	cli.Config().AccessToken = accessToken
-	if err := cli.Config().Dump(); err != nil {
-		return fmt.Errorf("couldn't save the credentials to the config file: %w", err)
+func saveSessionAndGenerateSSHIdentity(
+	ctx context.Context,
+	cli labcli.CLI,
+	sessionID string,
+	accessToken string,
+	refreshToken string,
+	expiresAt time.Time,
+	opts loginOptions,
+) error {
+	store := credstore.New(cli.Config().CredentialStore, cli.Config())
+	if err := store.Save(sessionID, accessToken, refreshToken, expiresAt); err != nil {
+		return err
+	}
+
+	if opts.skipSSH {
+		return nil
 	}
 
-	if err := ssh.GenerateIdentity(cli.Config().SSHDir); err != nil {
-		return fmt.Errorf("couldn't generate SSH identity in %s: %w", cli.Config().SSHDir, err)
+	sshDir := cli.Config().SSHDir
+	if err := ssh.GenerateIdentity(sshDir); err != nil {
+		return fmt.Errorf("couldn't generate SSH identity in %s: %w", sshDir, err)
+	}
+
+	if !opts.noUploadKey {
+		if err := uploadSSHKey(ctx, cli, sshDir); err != nil {
+			cli.PrintAux("Warning: couldn't upload the SSH public key to your Labs account: %s\n", err)
+		}
 	}
 
 	return nil
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/iximiuz/labctl/internal/credstore"
+	"github.com/iximiuz/labctl/internal/labcli"
+)
+
+type logoutOptions struct {
+	profile string
+}
+
+func newLogoutCommand(cli labcli.CLI) *cobra.Command {
+	var opts logoutOptions
+
+	cmd := &cobra.Command{
+		Use:   "logout",
+		Short: "Log out of a Labs profile",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return labcli.WrapStatusError(runLogout(cli, opts))
+		},
+	}
+
+	cmd.Flags().StringVar(
+		&opts.profile,
+		"profile",
+		"",
+		`Named profile to log out of (default: $LABCTL_PROFILE or "default")`,
+	)
+
+	return cmd
+}
+
+func runLogout(cli labcli.CLI, opts logoutOptions) error {
+	profile := resolveProfile(opts.profile)
+	if err := cli.SelectProfile(profile); err != nil {
+		return fmt.Errorf("couldn't select profile %q: %w", profile, err)
+	}
+
+	if cli.Config().SessionID == "" {
+		return labcli.NewStatusError(1, fmt.Sprintf("Not logged in to profile %q.", profile))
+	}
+
+	store := credstore.New(cli.Config().CredentialStore, cli.Config())
+	if err := store.Clear(); err != nil {
+		return fmt.Errorf("couldn't clear the stored credentials: %w", err)
+	}
+
+	cli.PrintAux("Logged out of profile %q.\n", profile)
+	return nil
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/iximiuz/labctl/internal/credstore"
+	"github.com/iximiuz/labctl/internal/labcli"
+)
+
+func newMigrateCredentialsCommand(cli labcli.CLI) *cobra.Command {
+	var profile string
+
+	cmd := &cobra.Command{
+		Use:   "migrate-credentials",
+		Short: "Move stored credentials to the configured credential store",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return labcli.WrapStatusError(runMigrateCredentials(cli, profile))
+		},
+	}
+
+	addProfileFlag(cmd, &profile)
+
+	return cmd
+}
+
+func runMigrateCredentials(cli labcli.CLI, profileFlag string) error {
+	if _, err := selectProfile(cli, profileFlag); err != nil {
+		return err
+	}
+
+	cfg := cli.Config()
+
+	accessToken, err := credstore.LoadAccessToken(cfg)
+	if err != nil {
+		return fmt.Errorf("couldn't read the current credentials: %w", err)
+	}
+	if cfg.SessionID == "" || accessToken == "" {
+		return labcli.NewStatusError(1, "Not logged in. Nothing to migrate.")
+	}
+
+	sessionID := cfg.SessionID
+	srcBackend := credstore.CurrentBackend(cfg)
+
+	dst := credstore.New(cfg.CredentialStore, cfg)
+	if err := dst.Save(sessionID, accessToken, cfg.RefreshToken, cfg.ExpiresAt); err != nil {
+		return fmt.Errorf("couldn't migrate the credentials: %w", err)
+	}
+
+	if srcBackend != credstore.CurrentBackend(cli.Config()) {
+		if err := credstore.EvictFromBackend(srcBackend, sessionID); err != nil {
+			return fmt.Errorf(
+				"credentials saved to the %q store but couldn't be cleared from the old %q store: %w",
+				cfg.CredentialStore, srcBackend, err,
+			)
+		}
+	}
+
+	cli.PrintAux("Credentials migrated to the %q store.\n", cfg.CredentialStore)
+	return nil
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/iximiuz/labctl/internal/labcli"
+)
+
+// stdinCredentials is the shape expected on stdin when --from-stdin is used.
+type stdinCredentials struct {
+	SessionID   string `json:"session_id"`
+	AccessToken string `json:"access_token"`
+}
+
+// runNonInteractiveLogin authenticates from environment variables or stdin,
+// without opening a browser or starting the spinner, so CI pipelines can log
+// in unattended.
+func runNonInteractiveLogin(ctx context.Context, cli labcli.CLI, opts loginOptions) error {
+	var sessionID, accessToken string
+
+	switch {
+	case opts.fromEnv:
+		sessionID = os.Getenv("LABCTL_SESSION_ID")
+		accessToken = os.Getenv("LABCTL_ACCESS_TOKEN")
+		if sessionID == "" || accessToken == "" {
+			return labcli.NewStatusError(1,
+				"LABCTL_SESSION_ID and LABCTL_ACCESS_TOKEN must both be set when using --from-env.",
+			)
+		}
+
+	case opts.fromStdin:
+		data, err := io.ReadAll(cli.InputStream())
+		if err != nil {
+			return fmt.Errorf("couldn't read credentials from stdin: %w", err)
+		}
+
+		var creds stdinCredentials
+		if err := json.Unmarshal(data, &creds); err != nil {
+			return labcli.NewStatusError(1, fmt.Sprintf("Invalid credentials JSON on stdin: %s", err))
+		}
+		if creds.SessionID == "" || creds.AccessToken == "" {
+			return labcli.NewStatusError(1, "Both session_id and access_token must be set in the stdin JSON blob.")
+		}
+		sessionID, accessToken = creds.SessionID, creds.AccessToken
+	}
+
+	cli.Client().SetCredentials(sessionID, accessToken)
+
+	if _, err := cli.Client().Me(ctx); err != nil {
+		return labcli.NewStatusError(1, fmt.Sprintf("Couldn't validate the provided credentials: %s", err))
+	}
+
+	if err := saveSessionAndGenerateSSHIdentity(ctx, cli, sessionID, accessToken, "", time.Time{}, opts); err != nil {
+		return err
+	}
+
+	cli.PrintAux("Authenticated.\n")
+	return nil
+}
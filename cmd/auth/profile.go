@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/iximiuz/labctl/internal/labcli"
+)
+
+// profileEnvVar lets CI and shell profiles pick an active profile without
+// having to pass --profile on every labctl invocation.
+const profileEnvVar = "LABCTL_PROFILE"
+
+// defaultProfile is used when neither --profile nor LABCTL_PROFILE is set,
+// matching the name the legacy single-account config is migrated into.
+const defaultProfile = "default"
+
+// resolveProfile applies the --profile / LABCTL_PROFILE / "default"
+// precedence shared by every profile-aware auth command.
+func resolveProfile(explicit string) string {
+	if explicit != "" {
+		return explicit
+	}
+	if v := os.Getenv(profileEnvVar); v != "" {
+		return v
+	}
+	return defaultProfile
+}
+
+// addProfileFlag registers the --profile flag shared by every auth command
+// that acts on a single named profile.
+func addProfileFlag(cmd *cobra.Command, profile *string) {
+	cmd.Flags().StringVar(
+		profile,
+		"profile",
+		"",
+		`Named profile to operate on (default: $LABCTL_PROFILE or "default")`,
+	)
+}
+
+// selectProfile resolves --profile / LABCTL_PROFILE / "default" and makes
+// it the active profile on cli, returning the resolved name for use in
+// messages.
+func selectProfile(cli labcli.CLI, explicit string) (string, error) {
+	profile := resolveProfile(explicit)
+	if err := cli.SelectProfile(profile); err != nil {
+		return "", fmt.Errorf("couldn't select profile %q: %w", profile, err)
+	}
+	return profile, nil
+}
@@ -0,0 +1,26 @@
+package auth
+
+import "testing"
+
+func TestResolveProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		explicit string
+		env      string
+		want     string
+	}{
+		{"explicit flag wins", "work", "personal", "work"},
+		{"falls back to env var", "", "personal", "personal"},
+		{"falls back to default", "", "", "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(profileEnvVar, tt.env)
+
+			if got := resolveProfile(tt.explicit); got != tt.want {
+				t.Errorf("resolveProfile(%q) with %s=%q = %q, want %q", tt.explicit, profileEnvVar, tt.env, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/iximiuz/labctl/internal/credstore"
+	"github.com/iximiuz/labctl/internal/labcli"
+)
+
+// sshPublicKeyFile is the file ssh.GenerateIdentity writes the generated
+// identity's public key to, relative to the SSH dir.
+const sshPublicKeyFile = "id_ed25519.pub"
+
+func newSSHKeysCommand(cli labcli.CLI) *cobra.Command {
+	var profile string
+
+	cmd := &cobra.Command{
+		Use:   "ssh-keys",
+		Short: "Manage SSH public keys uploaded to your Labs account",
+	}
+
+	cmd.PersistentFlags().StringVar(
+		&profile,
+		"profile",
+		"",
+		`Named profile to operate on (default: $LABCTL_PROFILE or "default")`,
+	)
+
+	cmd.AddCommand(newSSHKeysListCommand(cli, &profile))
+	cmd.AddCommand(newSSHKeysDeleteCommand(cli, &profile))
+
+	return cmd
+}
+
+func newSSHKeysListCommand(cli labcli.CLI, profile *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List SSH public keys uploaded to your Labs account",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return labcli.WrapStatusError(runSSHKeysList(cmd.Context(), cli, *profile))
+		},
+	}
+}
+
+func runSSHKeysList(ctx context.Context, cli labcli.CLI, profileFlag string) error {
+	if _, err := selectProfile(cli, profileFlag); err != nil {
+		return err
+	}
+
+	keys, err := credstore.WrapClient(cli).ListSSHKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't list SSH keys: %w", err)
+	}
+
+	if len(keys) == 0 {
+		cli.PrintAux("No SSH keys uploaded.\n")
+		return nil
+	}
+
+	for _, k := range keys {
+		cli.PrintAux("%s  %s  (added %s)\n", k.Fingerprint, k.Label, k.CreatedAt.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+func newSSHKeysDeleteCommand(cli labcli.CLI, profile *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <fingerprint>",
+		Short: "Delete an SSH public key from your Labs account",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return labcli.WrapStatusError(runSSHKeysDelete(cmd.Context(), cli, *profile, args[0]))
+		},
+	}
+}
+
+func runSSHKeysDelete(ctx context.Context, cli labcli.CLI, profileFlag string, fingerprint string) error {
+	if _, err := selectProfile(cli, profileFlag); err != nil {
+		return err
+	}
+
+	return credstore.WrapClient(cli).DeleteSSHKey(ctx, fingerprint)
+}
+
+// uploadSSHKey reads the public key generated in sshDir and uploads it to
+// the Labs account, tagged with the local hostname and today's date so
+// users can tell their machines' keys apart in 'ssh-keys list'. It's a
+// no-op (not an error) if a key with the same fingerprint is already on the
+// account, so repeated logins on the same machine stay idempotent.
+func uploadSSHKey(ctx context.Context, cli labcli.CLI, sshDir string) error {
+	client := credstore.WrapClient(cli)
+
+	pub, err := os.ReadFile(filepath.Join(sshDir, sshPublicKeyFile))
+	if err != nil {
+		return fmt.Errorf("couldn't read the generated SSH public key: %w", err)
+	}
+
+	existing, err := client.ListSSHKeys(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't list existing SSH keys: %w", err)
+	}
+
+	fingerprint, err := sshKeyFingerprint(string(pub))
+	if err != nil {
+		return fmt.Errorf("couldn't compute the public key's fingerprint: %w", err)
+	}
+
+	for _, k := range existing {
+		if k.Fingerprint == fingerprint {
+			return nil
+		}
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown-host"
+	}
+	label := fmt.Sprintf("%s (%s)", host, time.Now().Format("2006-01-02"))
+
+	if err := client.UploadSSHKey(ctx, string(pub), label); err != nil {
+		return fmt.Errorf("couldn't upload the SSH public key: %w", err)
+	}
+
+	return nil
+}
+
+// sshKeyFingerprint computes the SHA256 fingerprint of an "authorized_keys"
+// -style public key line, in the same "SHA256:<base64, no padding>" format
+// `ssh-keygen -l` and the Labs API report, so it can be compared against
+// ListSSHKeys results to detect a key already uploaded from this machine.
+func sshKeyFingerprint(pub string) (string, error) {
+	fields := strings.Fields(pub)
+	if len(fields) < 2 {
+		return "", fmt.Errorf("malformed public key: expected at least a type and key material")
+	}
+
+	keyBytes, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return "", fmt.Errorf("couldn't decode public key material: %w", err)
+	}
+
+	sum := sha256.Sum256(keyBytes)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:]), nil
+}
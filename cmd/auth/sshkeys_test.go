@@ -0,0 +1,22 @@
+package auth
+
+import "testing"
+
+func TestSSHKeyFingerprint(t *testing.T) {
+	const pub = "ssh-ed25519 ZmFrZS1lZDI1NTE5LXB1YmxpYy1rZXktYnl0ZXMtZm9yLXRlc3Q= test@example.com"
+	const want = "SHA256:ydJChcawjPIc+SLLW7Gckyvf1ZT6VGheMQB+4lvXh6Q"
+
+	got, err := sshKeyFingerprint(pub)
+	if err != nil {
+		t.Fatalf("sshKeyFingerprint returned an error: %v", err)
+	}
+	if got != want {
+		t.Errorf("sshKeyFingerprint(%q) = %q, want %q", pub, got, want)
+	}
+}
+
+func TestSSHKeyFingerprintMalformed(t *testing.T) {
+	if _, err := sshKeyFingerprint("not-a-valid-key-line"); err == nil {
+		t.Error("expected an error for a key line without key material, got nil")
+	}
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/iximiuz/labctl/internal/credstore"
+	"github.com/iximiuz/labctl/internal/labcli"
+)
+
+func newStatusCommand(cli labcli.CLI) *cobra.Command {
+	var profile string
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the current session's credential status",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return labcli.WrapStatusError(runStatus(cli, profile))
+		},
+	}
+
+	addProfileFlag(cmd, &profile)
+
+	return cmd
+}
+
+// runStatus is a read-only diagnostic: it never performs a refresh itself,
+// only reports whether one is pending, so it's safe to run without
+// mutating stored credentials.
+func runStatus(cli labcli.CLI, profileFlag string) error {
+	if _, err := selectProfile(cli, profileFlag); err != nil {
+		return err
+	}
+
+	cfg := cli.Config()
+
+	if cfg.SessionID == "" {
+		cli.PrintAux("Not logged in. Use 'labctl auth login' to authenticate.\n")
+		return nil
+	}
+
+	token, err := credstore.LoadAccessToken(cfg)
+	if err != nil || token == "" {
+		cli.PrintAux("Not logged in. Use 'labctl auth login' to authenticate.\n")
+		return nil
+	}
+
+	cli.PrintAux("Session ID: %s\n", cfg.SessionID)
+
+	if cfg.ExpiresAt.IsZero() {
+		cli.PrintAux("Access token: valid (no expiration reported)\n")
+		return nil
+	}
+
+	ttl := time.Until(cfg.ExpiresAt)
+	if ttl <= 0 {
+		cli.PrintAux("Access token: expired %s ago\n", (-ttl).Round(time.Second))
+	} else {
+		cli.PrintAux("Access token: valid for %s\n", ttl.Round(time.Second))
+	}
+
+	switch {
+	case !credstore.NeedsRefresh(cfg.ExpiresAt, time.Now()):
+		cli.PrintAux("Refresh: not needed yet\n")
+	case cfg.RefreshToken == "":
+		cli.PrintAux("Refresh: pending but not possible, no refresh token on file. Please log in again.\n")
+	default:
+		cli.PrintAux("Refresh: pending, will happen automatically on the next authenticated request\n")
+	}
+
+	return nil
+}
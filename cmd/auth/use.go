@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/iximiuz/labctl/internal/labcli"
+)
+
+func newUseCommand(cli labcli.CLI) *cobra.Command {
+	return &cobra.Command{
+		Use:   "use <profile>",
+		Short: "Switch the active Labs profile used by every labctl command",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return labcli.WrapStatusError(runUse(cli, args[0]))
+		},
+	}
+}
+
+func runUse(cli labcli.CLI, profile string) error {
+	if err := cli.SelectProfile(profile); err != nil {
+		return fmt.Errorf("couldn't select profile %q: %w", profile, err)
+	}
+
+	if err := cli.Config().SetCurrentProfile(profile); err != nil {
+		return fmt.Errorf("couldn't persist the active profile: %w", err)
+	}
+
+	cli.PrintAux("Switched to profile %q.\n", profile)
+	return nil
+}
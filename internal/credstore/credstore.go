@@ -0,0 +1,179 @@
+// Package credstore abstracts over where labctl keeps a logged-in user's
+// credentials, so the file-based config isn't the only option available.
+package credstore
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zalando/go-keyring"
+
+	"github.com/iximiuz/labctl/internal/labcli"
+)
+
+const keyringService = "labctl"
+
+// CredentialStore persists a login session's credentials on behalf of the
+// auth commands.
+type CredentialStore interface {
+	Save(sessionID, accessToken, refreshToken string, expiresAt time.Time) error
+	Clear() error
+}
+
+// FileStore is the original credential store: session ID and access token
+// are written in plaintext to the labctl config file.
+type FileStore struct {
+	cfg *labcli.Config
+}
+
+func NewFileStore(cfg *labcli.Config) *FileStore {
+	return &FileStore{cfg: cfg}
+}
+
+func (s *FileStore) Save(sessionID, accessToken, refreshToken string, expiresAt time.Time) error {
+	s.cfg.SessionID = sessionID
+	s.cfg.AccessToken = accessToken
+	s.cfg.RefreshToken = refreshToken
+	s.cfg.ExpiresAt = expiresAt
+
+	if err := s.cfg.Dump(); err != nil {
+		return fmt.Errorf("couldn't save the credentials to the config file: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FileStore) Clear() error {
+	s.cfg.SessionID = ""
+	s.cfg.AccessToken = ""
+	s.cfg.RefreshToken = ""
+	s.cfg.ExpiresAt = time.Time{}
+
+	return s.cfg.Dump()
+}
+
+// KeyringStore keeps the access token in the OS secret store (macOS
+// Keychain, GNOME libsecret, Windows Credential Manager) and leaves only the
+// session ID and refresh token -- useless without the access token -- in
+// the plaintext config file.
+type KeyringStore struct {
+	cfg *labcli.Config
+}
+
+func NewKeyringStore(cfg *labcli.Config) *KeyringStore {
+	return &KeyringStore{cfg: cfg}
+}
+
+func (s *KeyringStore) Save(sessionID, accessToken, refreshToken string, expiresAt time.Time) error {
+	if err := keyring.Set(keyringService, sessionID, accessToken); err != nil {
+		return fmt.Errorf("couldn't save the access token to the OS keyring: %w", err)
+	}
+
+	s.cfg.SessionID = sessionID
+	s.cfg.AccessToken = ""
+	s.cfg.RefreshToken = refreshToken
+	s.cfg.ExpiresAt = expiresAt
+
+	if err := s.cfg.Dump(); err != nil {
+		return fmt.Errorf("couldn't save the session pointer to the config file: %w", err)
+	}
+
+	return nil
+}
+
+func (s *KeyringStore) Clear() error {
+	if s.cfg.SessionID != "" {
+		if err := keyring.Delete(keyringService, s.cfg.SessionID); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			return fmt.Errorf("couldn't remove the access token from the OS keyring: %w", err)
+		}
+	}
+
+	s.cfg.SessionID = ""
+	s.cfg.AccessToken = ""
+	s.cfg.RefreshToken = ""
+	s.cfg.ExpiresAt = time.Time{}
+
+	return s.cfg.Dump()
+}
+
+// LoadAccessToken returns cfg's access token, transparently reading it back
+// out of the OS keyring when the config file only holds a session pointer.
+func LoadAccessToken(cfg *labcli.Config) (string, error) {
+	if cfg.AccessToken != "" {
+		return cfg.AccessToken, nil
+	}
+	if cfg.SessionID == "" {
+		return "", nil
+	}
+	return keyring.Get(keyringService, cfg.SessionID)
+}
+
+// CurrentBackend reports which backend is actually holding cfg's
+// credentials right now, based on where the access token lives, regardless
+// of what cfg.CredentialStore is currently configured to. Used by migration
+// to know what to clear once the credentials have moved.
+func CurrentBackend(cfg *labcli.Config) string {
+	if cfg.AccessToken != "" {
+		return "file"
+	}
+	return "keyring"
+}
+
+// EvictFromBackend removes sessionID's secret from the given backend,
+// without touching any other backend's copy. It's the narrower counterpart
+// to CredentialStore.Clear(), used when migrating credentials from one
+// backend to another: the destination's Save has already taken care of
+// persisting the session pointer, so only the stale secret needs to go.
+func EvictFromBackend(backend string, sessionID string) error {
+	if backend != "keyring" || sessionID == "" {
+		return nil
+	}
+	if err := keyring.Delete(keyringService, sessionID); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// New picks a CredentialStore implementation for the given backend name
+// ("file", "keyring" or "auto"), resolving "auto" to the keyring wherever
+// one is actually available on the current OS. Resolving to the keyring
+// backend opportunistically migrates a plaintext access token left over
+// from a profile that predates the keyring backend (or was last used with
+// --credential-store=file), so switching backends doesn't require always
+// running 'labctl auth migrate-credentials' by hand.
+func New(backend string, cfg *labcli.Config) CredentialStore {
+	switch backend {
+	case "keyring":
+		return newKeyringStoreMigratingPlaintext(cfg)
+	case "auto":
+		if keyringAvailable() {
+			return newKeyringStoreMigratingPlaintext(cfg)
+		}
+		return NewFileStore(cfg)
+	default:
+		return NewFileStore(cfg)
+	}
+}
+
+func newKeyringStoreMigratingPlaintext(cfg *labcli.Config) *KeyringStore {
+	if cfg.SessionID != "" && cfg.AccessToken != "" {
+		if err := keyring.Set(keyringService, cfg.SessionID, cfg.AccessToken); err == nil {
+			cfg.AccessToken = ""
+			_ = cfg.Dump()
+		}
+	}
+
+	return NewKeyringStore(cfg)
+}
+
+func keyringAvailable() bool {
+	const probeUser = "labctl-keyring-probe"
+
+	if err := keyring.Set(keyringService, probeUser, "probe"); err != nil {
+		return false
+	}
+	_ = keyring.Delete(keyringService, probeUser)
+
+	return true
+}
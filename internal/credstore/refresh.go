@@ -0,0 +1,169 @@
+package credstore
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/gofrs/flock"
+
+	"github.com/iximiuz/labctl/internal/labcli"
+)
+
+// RefreshSkew is how far ahead of the actual expiration a token is
+// proactively refreshed, so a request that's already in flight doesn't
+// race a token that expires mid-call.
+const RefreshSkew = 30 * time.Second
+
+// NeedsRefresh reports whether a token expiring at expiresAt should be
+// refreshed now, proactively accounting for RefreshSkew. A zero expiresAt
+// means the server didn't report an expiration, so there's nothing to
+// refresh against.
+func NeedsRefresh(expiresAt, now time.Time) bool {
+	if expiresAt.IsZero() {
+		return false
+	}
+	return !expiresAt.After(now.Add(RefreshSkew))
+}
+
+// tokenSource refreshes a single CLI's active profile and persists the
+// rotated tokens back to the configured credential store. It's safe for
+// concurrent use within a single process; the on-disk write is
+// additionally file-locked so multiple labctl processes sharing the same
+// config don't clobber each other's refresh.
+type tokenSource struct {
+	mu  sync.Mutex
+	cli labcli.CLI
+}
+
+// EnsureFreshToken refreshes cli's active session if its access token is
+// expired or about to expire. It returns an error, without refreshing, if
+// no access token is stored.
+func EnsureFreshToken(ctx context.Context, cli labcli.CLI) error {
+	_, err := (&tokenSource{cli: cli}).ensure(ctx)
+	return err
+}
+
+func (t *tokenSource) ensure(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cfg := t.cli.Config()
+
+	accessToken, err := LoadAccessToken(cfg)
+	if err != nil {
+		return "", fmt.Errorf("couldn't read the stored access token: %w", err)
+	}
+	if accessToken == "" {
+		return "", labcli.NewStatusError(1, "Not logged in. Use 'labctl auth login' first.")
+	}
+
+	if !NeedsRefresh(cfg.ExpiresAt, time.Now()) {
+		t.cli.Client().SetCredentials(cfg.SessionID, accessToken)
+		return accessToken, nil
+	}
+
+	return t.refresh(ctx, accessToken)
+}
+
+// refresh swaps the current refresh token for a new access token and
+// persists the result to the configured credential store.
+func (t *tokenSource) refresh(ctx context.Context, staleAccessToken string) (string, error) {
+	cfg := t.cli.Config()
+	if cfg.RefreshToken == "" {
+		return "", labcli.NewStatusError(1,
+			"Session expired and no refresh token is available. Use 'labctl auth login' again.",
+		)
+	}
+
+	lock := flock.New(configLockPath(cfg))
+	if err := lock.Lock(); err != nil {
+		return "", fmt.Errorf("couldn't lock the config file for refresh: %w", err)
+	}
+	defer lock.Unlock()
+
+	t.cli.Client().SetCredentials(cfg.SessionID, staleAccessToken)
+
+	resp, err := t.cli.Client().RefreshToken(ctx, cfg.RefreshToken)
+	if err != nil {
+		return "", fmt.Errorf("couldn't refresh the access token: %w", err)
+	}
+
+	t.cli.Client().SetCredentials(cfg.SessionID, resp.AccessToken)
+
+	store := New(cfg.CredentialStore, cfg)
+	if err := store.Save(cfg.SessionID, resp.AccessToken, resp.RefreshToken, resp.ExpiresAt); err != nil {
+		return "", fmt.Errorf("couldn't save the refreshed credentials: %w", err)
+	}
+
+	return resp.AccessToken, nil
+}
+
+// configLockPath returns the path of the lock file guarding concurrent
+// writes to the config file, next to the profile's SSH identity.
+func configLockPath(cfg *labcli.Config) string {
+	return filepath.Join(filepath.Dir(cfg.SSHDir), "config.lock")
+}
+
+// WrapClient returns a labcli.Client that transparently refreshes cli's
+// access token before every authenticated call, so commands outside
+// cmd/auth get the same automatic renewal auth's own subcommands do
+// without having to call EnsureFreshToken by hand.
+func WrapClient(cli labcli.CLI) labcli.Client {
+	return &refreshingClient{cli: cli}
+}
+
+type refreshingClient struct {
+	cli labcli.CLI
+}
+
+func (c *refreshingClient) SetCredentials(sessionID, accessToken string) {
+	c.cli.Client().SetCredentials(sessionID, accessToken)
+}
+
+// CreateSession, GetSession and RefreshToken are part of the login/refresh
+// bootstrap itself, so they're passed straight through: there's no token
+// to ensure fresh yet (CreateSession/GetSession) or doing so would be
+// circular (RefreshToken).
+
+func (c *refreshingClient) CreateSession(ctx context.Context) (*labcli.Session, error) {
+	return c.cli.Client().CreateSession(ctx)
+}
+
+func (c *refreshingClient) GetSession(ctx context.Context, id string) (*labcli.Session, error) {
+	return c.cli.Client().GetSession(ctx, id)
+}
+
+func (c *refreshingClient) RefreshToken(ctx context.Context, refreshToken string) (*labcli.TokenPair, error) {
+	return c.cli.Client().RefreshToken(ctx, refreshToken)
+}
+
+func (c *refreshingClient) Me(ctx context.Context) (*labcli.User, error) {
+	if err := EnsureFreshToken(ctx, c.cli); err != nil {
+		return nil, err
+	}
+	return c.cli.Client().Me(ctx)
+}
+
+func (c *refreshingClient) ListSSHKeys(ctx context.Context) ([]labcli.SSHKey, error) {
+	if err := EnsureFreshToken(ctx, c.cli); err != nil {
+		return nil, err
+	}
+	return c.cli.Client().ListSSHKeys(ctx)
+}
+
+func (c *refreshingClient) UploadSSHKey(ctx context.Context, publicKey, label string) error {
+	if err := EnsureFreshToken(ctx, c.cli); err != nil {
+		return err
+	}
+	return c.cli.Client().UploadSSHKey(ctx, publicKey, label)
+}
+
+func (c *refreshingClient) DeleteSSHKey(ctx context.Context, fingerprint string) error {
+	if err := EnsureFreshToken(ctx, c.cli); err != nil {
+		return err
+	}
+	return c.cli.Client().DeleteSSHKey(ctx, fingerprint)
+}
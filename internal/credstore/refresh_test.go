@@ -0,0 +1,31 @@
+package credstore
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNeedsRefresh(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		expiresAt time.Time
+		want      bool
+	}{
+		{"zero expiration never refreshes", time.Time{}, false},
+		{"well within TTL", now.Add(5 * time.Minute), false},
+		{"already expired", now.Add(-time.Minute), true},
+		{"inside the refresh skew", now.Add(RefreshSkew / 2), true},
+		{"exactly at the refresh skew boundary", now.Add(RefreshSkew), true},
+		{"just outside the refresh skew", now.Add(RefreshSkew + time.Second), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NeedsRefresh(tt.expiresAt, now); got != tt.want {
+				t.Errorf("NeedsRefresh(%v, %v) = %v, want %v", tt.expiresAt, now, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package labcli
+
+import (
+	"context"
+	"time"
+)
+
+// Client is the Labs API client every labctl command talks to through
+// CLI.Client().
+type Client interface {
+	// SetCredentials attaches the session ID and access token subsequent
+	// calls authenticate with.
+	SetCredentials(sessionID, accessToken string)
+
+	CreateSession(ctx context.Context) (*Session, error)
+	GetSession(ctx context.Context, id string) (*Session, error)
+	RefreshToken(ctx context.Context, refreshToken string) (*TokenPair, error)
+	Me(ctx context.Context) (*User, error)
+
+	ListSSHKeys(ctx context.Context) ([]SSHKey, error)
+	UploadSSHKey(ctx context.Context, publicKey, label string) error
+	DeleteSSHKey(ctx context.Context, fingerprint string) error
+}
+
+// Session is a login session as returned by the Labs API, whether freshly
+// created or fetched while polling for authorization.
+type Session struct {
+	ID            string
+	AuthURL       string
+	AccessToken   string
+	RefreshToken  string
+	ExpiresAt     time.Time
+	Authenticated bool
+}
+
+// TokenPair is the result of exchanging a refresh token for a new access
+// token.
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+// User is the authenticated user, as returned by Client.Me.
+type User struct {
+	ID       string
+	Username string
+}
+
+// SSHKey is an SSH public key uploaded to a user's Labs account.
+type SSHKey struct {
+	Fingerprint string
+	Label       string
+	CreatedAt   time.Time
+}
@@ -0,0 +1,136 @@
+package labcli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultProfileName is the profile used when neither --profile nor
+// LABCTL_PROFILE selects one, and the name a legacy single-account config
+// is migrated into.
+const DefaultProfileName = "default"
+
+// Config is a single profile's persisted settings and credentials.
+type Config struct {
+	SessionID       string    `json:"session_id,omitempty"`
+	AccessToken     string    `json:"access_token,omitempty"`
+	RefreshToken    string    `json:"refresh_token,omitempty"`
+	ExpiresAt       time.Time `json:"expires_at,omitempty"`
+	SSHDir          string    `json:"ssh_dir"`
+	CredentialStore string    `json:"credential_store,omitempty"`
+
+	name string
+	set  *ProfileSet
+}
+
+// Dump persists this profile's in-memory changes to disk, as part of the
+// profile set it belongs to.
+func (c *Config) Dump() error {
+	if c.set == nil {
+		return fmt.Errorf("config %q isn't attached to a loaded profile set", c.name)
+	}
+	return c.set.dump()
+}
+
+// SetCurrentProfile makes name the profile every labctl command operates on
+// by default, and persists the choice to disk.
+func (c *Config) SetCurrentProfile(name string) error {
+	if c.set == nil {
+		return fmt.Errorf("config %q isn't attached to a loaded profile set", c.name)
+	}
+	return c.set.setCurrent(name)
+}
+
+// ProfileSet is the on-disk shape of the labctl config file: a named map of
+// profiles plus a pointer to the active one.
+type ProfileSet struct {
+	Profiles map[string]*Config `json:"profiles"`
+	Current  string             `json:"current"`
+
+	path string
+}
+
+// LoadProfileSet reads the config file at path, transparently migrating a
+// legacy single-account config (a flat Config object, rather than a map of
+// profiles) into the DefaultProfileName profile on first read. A missing
+// file is not an error: it's treated as an empty, freshly initialized
+// profile set.
+func LoadProfileSet(path string) (*ProfileSet, error) {
+	ps := &ProfileSet{path: path, Profiles: map[string]*Config{}}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		// Nothing on disk yet.
+	case err != nil:
+		return nil, fmt.Errorf("couldn't read config file %s: %w", path, err)
+	default:
+		if jerr := json.Unmarshal(data, ps); jerr != nil || len(ps.Profiles) == 0 {
+			var legacy Config
+			if lerr := json.Unmarshal(data, &legacy); lerr != nil {
+				return nil, fmt.Errorf("couldn't parse config file %s: %w", path, jerr)
+			}
+			ps.Profiles = map[string]*Config{DefaultProfileName: &legacy}
+			ps.Current = DefaultProfileName
+		}
+	}
+
+	if ps.Current == "" {
+		ps.Current = DefaultProfileName
+	}
+
+	for name, cfg := range ps.Profiles {
+		cfg.name = name
+		cfg.set = ps
+	}
+	ps.ensure(ps.Current)
+
+	return ps, nil
+}
+
+// Select switches the active profile, creating it (empty, with its own SSH
+// dir next to the config file) on first use, and returns its Config.
+func (ps *ProfileSet) Select(name string) *Config {
+	ps.ensure(name)
+	ps.Current = name
+	return ps.Profiles[name]
+}
+
+// CurrentConfig returns the active profile's Config.
+func (ps *ProfileSet) CurrentConfig() *Config {
+	return ps.Profiles[ps.Current]
+}
+
+func (ps *ProfileSet) setCurrent(name string) error {
+	ps.ensure(name)
+	ps.Current = name
+	return ps.dump()
+}
+
+// ensure creates an empty profile named name if one doesn't already exist.
+func (ps *ProfileSet) ensure(name string) {
+	if _, ok := ps.Profiles[name]; ok {
+		return
+	}
+	ps.Profiles[name] = &Config{
+		name:   name,
+		set:    ps,
+		SSHDir: filepath.Join(filepath.Dir(ps.path), name, "ssh"),
+	}
+}
+
+func (ps *ProfileSet) dump() error {
+	data, err := json.MarshalIndent(ps, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't marshal config: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(ps.path), 0o700); err != nil {
+		return fmt.Errorf("couldn't create config dir: %w", err)
+	}
+
+	return os.WriteFile(ps.path, data, 0o600)
+}
@@ -0,0 +1,67 @@
+// Package labcli defines the interface every labctl command is written
+// against -- the active profile's Config, the Labs API Client, and the
+// process's standard streams -- plus the shared error type commands use to
+// control their own exit code.
+package labcli
+
+import "io"
+
+// CLI is the facade every labctl command receives. A single CLI is shared
+// process-wide; SelectProfile switches which profile's Config and Client
+// subsequent calls operate on.
+type CLI interface {
+	// Config returns the active profile's persisted settings and
+	// credentials.
+	Config() *Config
+
+	// Client returns a Labs API client authenticated for the active
+	// profile.
+	Client() Client
+
+	// PrintAux writes a formatted line to the auxiliary output stream
+	// (status messages, prompts -- anything that isn't a command's
+	// primary stdout payload).
+	PrintAux(format string, args ...interface{})
+
+	// AuxStream returns the writer PrintAux writes to, for callers (like
+	// the login spinner) that need direct access to it.
+	AuxStream() io.Writer
+
+	// InputStream returns the stream non-interactive commands read input
+	// from (normally os.Stdin).
+	InputStream() io.Reader
+
+	// SelectProfile makes name the profile Config and Client operate on
+	// for the remainder of the command, creating it (empty) on first use.
+	SelectProfile(name string) error
+}
+
+// StatusError is an error that also carries the process exit code it
+// should cause, so a command's RunE can return a single error value that
+// fully determines both the printed message and the exit status.
+type StatusError struct {
+	Code    int
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return e.Message
+}
+
+// NewStatusError builds a StatusError with the given exit code and message.
+func NewStatusError(code int, message string) *StatusError {
+	return &StatusError{Code: code, Message: message}
+}
+
+// WrapStatusError is how every labctl command's RunE returns its result:
+// nil and *StatusErrors pass through untouched, anything else is wrapped
+// with a generic exit code of 1.
+func WrapStatusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if se, ok := err.(*StatusError); ok {
+		return se
+	}
+	return NewStatusError(1, err.Error())
+}
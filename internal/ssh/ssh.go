@@ -0,0 +1,55 @@
+// Package ssh generates the local SSH identity labctl uses to connect to
+// Labs environments.
+package ssh
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	privateKeyFile = "id_ed25519"
+	publicKeyFile  = "id_ed25519.pub"
+)
+
+// GenerateIdentity creates an ed25519 keypair in dir, named id_ed25519 /
+// id_ed25519.pub. It's a no-op if a private key is already there, so
+// repeated logins on the same machine keep reusing the same identity.
+func GenerateIdentity(dir string) error {
+	if _, err := os.Stat(filepath.Join(dir, privateKeyFile)); err == nil {
+		return nil
+	}
+
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("couldn't generate an ed25519 keypair: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("couldn't create SSH dir %s: %w", dir, err)
+	}
+
+	privBlock, err := ssh.MarshalPrivateKey(priv, "")
+	if err != nil {
+		return fmt.Errorf("couldn't marshal the private key: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, privateKeyFile), pem.EncodeToMemory(privBlock), 0o600); err != nil {
+		return fmt.Errorf("couldn't write the private key: %w", err)
+	}
+
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		return fmt.Errorf("couldn't derive the public key: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, publicKeyFile), ssh.MarshalAuthorizedKey(sshPub), 0o644); err != nil {
+		return fmt.Errorf("couldn't write the public key: %w", err)
+	}
+
+	return nil
+}